@@ -3,10 +3,12 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/resolvers"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 )
 
 // DefaultReferencesPageSize is the reference result page size when no limit is supplied.
@@ -18,6 +20,33 @@ const DefaultDiagnosticsPageSize = 100
 // ErrIllegalLimit occurs when the user requests less than one object per page.
 var ErrIllegalLimit = errors.New("illegal limit")
 
+// rateLimitGraphQLError wraps a *ratelimit.RateLimitError so it serializes with a machine-readable
+// extension, letting clients honor backoff programmatically instead of parsing the error string.
+type rateLimitGraphQLError struct {
+	err *ratelimit.RateLimitError
+}
+
+func (e *rateLimitGraphQLError) Error() string { return e.err.Error() }
+
+// Extensions implements the interface graphql-go looks for when serializing errors.
+func (e *rateLimitGraphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":              "RATE_LIMITED",
+		"retryAfterSeconds": int(e.err.RetryAfter / time.Second),
+	}
+}
+
+// mapRateLimitError translates a *ratelimit.RateLimitError coming out of the underlying resolver
+// into a GraphQL error carrying a {"code":"RATE_LIMITED","retryAfterSeconds":N} extension, leaving
+// every other error untouched.
+func mapRateLimitError(err error) error {
+	var rlErr *ratelimit.RateLimitError
+	if errors.As(err, &rlErr) {
+		return &rateLimitGraphQLError{err: rlErr}
+	}
+	return err
+}
+
 // QueryResolver is the main interface to bundle-related operations exposed to the GraphQL API. This
 // resolver concerns itself with GraphQL/API-specific behaviors (auth, validation, marshaling, etc.).
 // All code intel-specific behavior is delegated to the underlying resolver instance, which is defined
@@ -47,7 +76,7 @@ func (r *QueryResolver) ToGitBlobLSIFData() (gql.GitBlobLSIFDataResolver, bool)
 func (r *QueryResolver) NavView(ctx context.Context, args gql.LSIFNavViewArgs) (gql.NavViewConnectionResolver, error) {
 	rangeViews, err := r.resolver.NavView(ctx)
 	if err != nil {
-		return nil, err
+		return nil, mapRateLimitError(err)
 	}
 
 	return &navViewConnectionResolver{
@@ -101,7 +130,7 @@ func (r *navRangeResolver) Hover(ctx context.Context) (gql.HoverResolver, error)
 func (r *QueryResolver) Definitions(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.LocationConnectionResolver, error) {
 	locations, err := r.resolver.Definitions(ctx, int(args.Line), int(args.Character))
 	if err != nil {
-		return nil, err
+		return nil, mapRateLimitError(err)
 	}
 
 	return NewLocationConnectionResolver(locations, nil, r.locationResolver), nil
@@ -119,7 +148,7 @@ func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQuery
 
 	locations, cursor, err := r.resolver.References(ctx, int(args.Line), int(args.Character), limit, cursor)
 	if err != nil {
-		return nil, err
+		return nil, mapRateLimitError(err)
 	}
 
 	return NewLocationConnectionResolver(locations, strPtr(cursor), r.locationResolver), nil
@@ -128,7 +157,7 @@ func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQuery
 func (r *QueryResolver) Hover(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.HoverResolver, error) {
 	text, rx, exists, err := r.resolver.Hover(ctx, int(args.Line), int(args.Character))
 	if err != nil || !exists {
-		return nil, err
+		return nil, mapRateLimitError(err)
 	}
 
 	return NewHoverResolver(text, convertRange(rx)), nil
@@ -142,7 +171,7 @@ func (r *QueryResolver) Diagnostics(ctx context.Context, args *gql.LSIFDiagnosti
 
 	diagnostics, totalCount, err := r.resolver.Diagnostics(ctx, limit)
 	if err != nil {
-		return nil, err
+		return nil, mapRateLimitError(err)
 	}
 
 	return NewDiagnosticConnectionResolver(diagnostics, totalCount, r.locationResolver), nil
@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// AdaptiveLimiterConfig configures an AdaptiveLimiter.
+type AdaptiveLimiterConfig struct {
+	// Max is the ceiling the limiter ramps back up towards once it has backed off.
+	Max rate.Limit
+	// Min is the floor the limiter will never back off below.
+	Min rate.Limit
+	// Burst is the burst size passed to the underlying token bucket.
+	Burst int
+
+	// DecreaseFactor is multiplied into the current limit whenever a 429/503 is reported, e.g. 0.5
+	// halves it. Defaults to 0.5.
+	DecreaseFactor float64
+	// IncreaseStep is added to the current limit on every successful response that isn't part of a
+	// post-backoff recovery ramp.
+	IncreaseStep rate.Limit
+	// RecoveryWindow is how long it takes to ramp from the post-backoff floor back to Max, assuming
+	// no further failures are reported in the meantime. Defaults to 5 minutes.
+	RecoveryWindow time.Duration
+	// WindowSize is the number of most recent Report outcomes kept to decide whether the service is
+	// still unhappy. Defaults to 20.
+	WindowSize int
+	// MinFailureRatio is the fraction of the last WindowSize outcomes that must be failures before
+	// a throttled Report triggers another back-off. This prevents a single stray 429/503 amid mostly
+	// successful traffic from re-halving a limit that's already recovering. Defaults to 0.2.
+	MinFailureRatio float64
+}
+
+// AdaptiveLimiter is a Limiter that tightens and relaxes its allowed rate based on the outcome of
+// the requests it gates, rather than on any rate-limit headers. Callers report the outcome of each
+// request via Report: a 429 or 503 response halves the current rate down to a configurable floor
+// and starts a linear ramp back towards the ceiling, while successful responses nudge the rate back
+// up. It implements the Limiter interface, so it is a drop-in replacement for BlockingLimiter or
+// NonBlockingLimiter for code hosts that signal overload without rate-limit headers.
+type AdaptiveLimiter struct {
+	cfg AdaptiveLimiterConfig
+
+	mu         sync.Mutex
+	limiter    *rate.Limiter
+	current    rate.Limit
+	rampFrom   rate.Limit // current limit at the start of the active recovery ramp
+	rampAt     time.Time  // when the active recovery ramp started; zero if not ramping
+	recent     []bool     // ring buffer of the last WindowSize outcomes; true means success
+	recentNext int
+
+	clock func() time.Time
+}
+
+// NewAdaptiveLimiter creates an AdaptiveLimiter that starts out allowing cfg.Max requests per
+// second.
+func NewAdaptiveLimiter(cfg AdaptiveLimiterConfig) *AdaptiveLimiter {
+	if cfg.DecreaseFactor <= 0 || cfg.DecreaseFactor >= 1 {
+		cfg.DecreaseFactor = 0.5
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = 20
+	}
+	if cfg.MinFailureRatio <= 0 {
+		cfg.MinFailureRatio = 0.2
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.RecoveryWindow <= 0 {
+		cfg.RecoveryWindow = 5 * time.Minute
+	}
+
+	return &AdaptiveLimiter{
+		cfg:     cfg,
+		limiter: rate.NewLimiter(cfg.Max, cfg.Burst),
+		current: cfg.Max,
+		recent:  make([]bool, 0, cfg.WindowSize),
+	}
+}
+
+// Limit blocks until al permits n events to happen, honoring whatever rate Report has most
+// recently settled on.
+func (al *AdaptiveLimiter) Limit(ctx context.Context, n int) error {
+	al.mu.Lock()
+	al.refreshLocked()
+	al.mu.Unlock()
+
+	return al.limiter.WaitN(ctx, n)
+}
+
+// Report records the outcome of a request gated by al so it can adjust its rate. resp may be nil
+// when err is non-nil (e.g. a network error, which is not treated as an overload signal); a nil
+// resp and nil err is treated as success.
+func (al *AdaptiveLimiter) Report(resp *http.Response, err error) {
+	throttled := err == nil && resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.recordLocked(!throttled)
+
+	if throttled {
+		// Only back off again if recent history agrees the service is actually unhappy; otherwise a
+		// single stray 429/503 amid mostly-successful traffic would re-halve a limit that's already
+		// on its way back up.
+		if al.failureRatioLocked() >= al.cfg.MinFailureRatio {
+			al.backOffLocked()
+		}
+		return
+	}
+
+	al.recoverLocked()
+}
+
+// failureRatioLocked returns the fraction of the last WindowSize outcomes that were failures.
+func (al *AdaptiveLimiter) failureRatioLocked() float64 {
+	if len(al.recent) == 0 {
+		return 0
+	}
+
+	failures := 0
+	for _, success := range al.recent {
+		if !success {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(al.recent))
+}
+
+// Stats reports the limiter's current effective rate and burst.
+func (al *AdaptiveLimiter) Stats() (current rate.Limit, burst int) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	al.refreshLocked()
+	return al.current, al.cfg.Burst
+}
+
+func (al *AdaptiveLimiter) recordLocked(success bool) {
+	if len(al.recent) < cap(al.recent) {
+		al.recent = append(al.recent, success)
+	} else {
+		al.recent[al.recentNext] = success
+	}
+	al.recentNext = (al.recentNext + 1) % cap(al.recent)
+}
+
+func (al *AdaptiveLimiter) backOffLocked() {
+	next := al.current * rate.Limit(al.cfg.DecreaseFactor)
+	if next < al.cfg.Min {
+		next = al.cfg.Min
+	}
+
+	al.current = next
+	al.rampFrom = next
+	al.rampAt = al.now()
+	al.limiter.SetLimit(next)
+}
+
+func (al *AdaptiveLimiter) recoverLocked() {
+	al.refreshLocked()
+
+	if !al.rampAt.IsZero() {
+		// A recovery ramp is already advancing this report's interval; let refreshLocked handle it.
+		return
+	}
+
+	next := al.current + al.cfg.IncreaseStep
+	if next > al.cfg.Max {
+		next = al.cfg.Max
+	}
+
+	al.current = next
+	al.limiter.SetLimit(next)
+}
+
+// refreshLocked recomputes the current limit from the active recovery ramp, if any, and applies it
+// to the underlying token bucket. al.mu must be held.
+func (al *AdaptiveLimiter) refreshLocked() {
+	if al.rampAt.IsZero() {
+		return
+	}
+
+	elapsed := al.now().Sub(al.rampAt)
+	if elapsed >= al.cfg.RecoveryWindow {
+		al.current = al.cfg.Max
+		al.rampAt = time.Time{}
+		al.limiter.SetLimit(al.current)
+		return
+	}
+
+	frac := float64(elapsed) / float64(al.cfg.RecoveryWindow)
+	al.current = al.rampFrom + rate.Limit(frac)*(al.cfg.Max-al.rampFrom)
+	al.limiter.SetLimit(al.current)
+}
+
+func (al *AdaptiveLimiter) now() time.Time {
+	if al.clock != nil {
+		return al.clock()
+	}
+	return time.Now()
+}
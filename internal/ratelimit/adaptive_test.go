@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// fakeClock lets tests move al's notion of "now" forward deterministically instead of sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newTestLimiter(cfg AdaptiveLimiterConfig, clock *fakeClock) *AdaptiveLimiter {
+	al := NewAdaptiveLimiter(cfg)
+	al.clock = func() time.Time { return clock.now }
+	return al
+}
+
+func resp(status int) *http.Response {
+	return &http.Response{StatusCode: status}
+}
+
+func TestAdaptiveLimiterBackOff(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	al := newTestLimiter(AdaptiveLimiterConfig{
+		Max:            rate.Limit(10),
+		Min:            rate.Limit(1),
+		DecreaseFactor: 0.5,
+	}, clock)
+
+	// A single failure is 100% of the (one-entry) recent window, so it always clears
+	// MinFailureRatio and backs off immediately.
+	al.Report(resp(http.StatusTooManyRequests), nil)
+
+	if got, _ := al.Stats(); got != 5 {
+		t.Fatalf("current rate after one 429 = %v, want 5", got)
+	}
+
+	al.Report(resp(http.StatusServiceUnavailable), nil)
+	if got, _ := al.Stats(); got != 2.5 {
+		t.Fatalf("current rate after second 503 = %v, want 2.5", got)
+	}
+}
+
+func TestAdaptiveLimiterBackOffRespectsFloor(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	al := newTestLimiter(AdaptiveLimiterConfig{
+		Max:            rate.Limit(10),
+		Min:            rate.Limit(4),
+		DecreaseFactor: 0.5,
+	}, clock)
+
+	al.Report(resp(http.StatusTooManyRequests), nil)
+	al.Report(resp(http.StatusTooManyRequests), nil)
+
+	if got, _ := al.Stats(); got != 4 {
+		t.Fatalf("current rate = %v, want floor of 4", got)
+	}
+}
+
+func TestAdaptiveLimiterRecoveryRampsLinearly(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	al := newTestLimiter(AdaptiveLimiterConfig{
+		Max:            rate.Limit(10),
+		Min:            rate.Limit(1),
+		DecreaseFactor: 0.5,
+		RecoveryWindow: 10 * time.Minute,
+	}, clock)
+
+	al.Report(resp(http.StatusTooManyRequests), nil) // current: 5, ramp starts at t=0
+
+	clock.advance(5 * time.Minute) // halfway through the ramp
+	if got, _ := al.Stats(); got != 7.5 {
+		t.Fatalf("current rate halfway through recovery = %v, want 7.5", got)
+	}
+
+	clock.advance(5 * time.Minute) // ramp complete
+	if got, _ := al.Stats(); got != 10 {
+		t.Fatalf("current rate after recovery window elapses = %v, want Max (10)", got)
+	}
+}
+
+func TestAdaptiveLimiterRecoveryWindowDefaulted(t *testing.T) {
+	// A zero RecoveryWindow must not let refreshLocked treat the ramp as already complete on the
+	// very next call: it should get a sane default instead of snapping straight back to Max.
+	al := NewAdaptiveLimiter(AdaptiveLimiterConfig{Max: rate.Limit(10), Min: rate.Limit(1)})
+	if al.cfg.RecoveryWindow <= 0 {
+		t.Fatalf("RecoveryWindow default = %v, want a positive default", al.cfg.RecoveryWindow)
+	}
+}
+
+func TestAdaptiveLimiterSuccessIncreasesRateUpToMax(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	al := newTestLimiter(AdaptiveLimiterConfig{
+		Max:          rate.Limit(10),
+		Min:          rate.Limit(1),
+		IncreaseStep: rate.Limit(1),
+	}, clock)
+	al.current = 9
+	al.limiter.SetLimit(9)
+
+	al.Report(resp(http.StatusOK), nil)
+	if got, _ := al.Stats(); got != 10 {
+		t.Fatalf("current rate after success = %v, want 10", got)
+	}
+
+	al.Report(resp(http.StatusOK), nil)
+	if got, _ := al.Stats(); got != 10 {
+		t.Fatalf("current rate should stay capped at Max (10), got %v", got)
+	}
+}
+
+func TestAdaptiveLimiterStrayFailureBelowRatioDoesNotBackOff(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	al := newTestLimiter(AdaptiveLimiterConfig{
+		Max:             rate.Limit(10),
+		Min:             rate.Limit(1),
+		WindowSize:      10,
+		MinFailureRatio: 0.5,
+	}, clock)
+
+	for i := 0; i < 9; i++ {
+		al.Report(resp(http.StatusOK), nil)
+	}
+	al.Report(resp(http.StatusTooManyRequests), nil)
+
+	if got, _ := al.Stats(); got != 10 {
+		t.Fatalf("a single 429 amid mostly-successful traffic should not back off, got %v", got)
+	}
+}
@@ -0,0 +1,235 @@
+package ratelimit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedResponse is a stored HTTP response, keyed by request URL + auth + a fixed set of
+// Vary-sensitive request headers.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+	MaxAge     time.Duration
+}
+
+func (c *CachedResponse) fresh(now time.Time) bool {
+	return c.MaxAge > 0 && now.Sub(c.StoredAt) < c.MaxAge
+}
+
+// toHTTPResponse builds a fresh *http.Response for a single caller: a new cloned Header and a new
+// reader over c.Body, never shared with any other caller. This matters because singleflight hands
+// the same *coalescedResult to every coalesced caller, and bytes.Reader (and the body it backs) is
+// not safe for concurrent reads from a shared cursor.
+func (c *CachedResponse) toHTTPResponse(req *http.Request, fromCache bool) *http.Response {
+	header := c.Header.Clone()
+	if fromCache {
+		// Tags this response so Monitor.Update's existing early-return skips it: a served-from-cache
+		// response carries stale RateLimit headers that must not be double-counted.
+		header.Set("X-From-Cache", "1")
+	}
+
+	return &http.Response{
+		StatusCode: c.StatusCode,
+		Status:     strconv.Itoa(c.StatusCode),
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader(c.Body)),
+		Request:    req,
+	}
+}
+
+// Cache is the storage backend used by CachingClient. NewMemoryCache provides a process-local
+// implementation; an on-disk implementation can satisfy the same interface for callers that want the
+// cache to survive process restarts.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+}
+
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]*CachedResponse
+}
+
+// NewMemoryCache returns a Cache backed by an in-memory map.
+func NewMemoryCache() Cache {
+	return &memoryCache{items: make(map[string]*CachedResponse)}
+}
+
+func (c *memoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.items[key]
+	return r, ok
+}
+
+func (c *memoryCache) Set(key string, resp *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = resp
+}
+
+// varyRequestHeaders are the request headers folded into the cache key, approximating per-Vary
+// caching without a second lookup keyed by the server's actual Vary response header.
+var varyRequestHeaders = []string{"Accept", "Accept-Encoding"}
+
+// CachingClient wraps an http.Client with a response cache and request coalescing, so repeated or
+// concurrent requests for the same resource during a large sync (e.g. repo-updater walking a code
+// host's API) don't each consume a rate-limit token. It respects ETag/If-None-Match and
+// Cache-Control: max-age as returned by GitHub/GitLab, and plugs directly into an existing Monitor
+// without changing its contract: responses served entirely from cache are tagged X-From-Cache so
+// Monitor.Update's existing early-return skips them, while a 304 revalidation still carries the
+// server's fresh rate-limit headers through to the caller's Monitor.Update.
+type CachingClient struct {
+	Client *http.Client
+	Cache  Cache
+
+	group singleflight.Group
+}
+
+// NewCachingClient creates a CachingClient that serves client's responses through cache. client
+// defaults to http.DefaultClient if nil.
+func NewCachingClient(client *http.Client, cache Cache) *CachingClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &CachingClient{Client: client, Cache: cache}
+}
+
+// coalescedResult is what singleflight.Group.Do hands to every coalesced caller of a given key. It
+// deliberately holds only immutable buffered data (no reader, no *http.Response) so each caller can
+// build its own independent Body via toHTTPResponse instead of racing over a shared one.
+type coalescedResult struct {
+	*CachedResponse
+	fromCache bool
+}
+
+// Do performs req, serving a cached response when one is still fresh or the server confirms it with
+// a 304, and coalescing identical concurrent requests into a single round trip. Each caller,
+// coalesced or not, gets back its own *http.Response with its own unshared Body reader.
+//
+// Caching and coalescing only apply to GET/HEAD: unlike those, a POST/PATCH/PUT/DELETE (e.g. a
+// GitHub GraphQL query, which is always a POST to the same URL) can carry a different body per call,
+// so keying solely on method+URL+headers would coalesce unrelated requests together and could later
+// serve one caller's response to another. Anything else goes straight through to the underlying
+// client, unmodified.
+func (c *CachingClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return c.Client.Do(req)
+	}
+
+	key := cacheKey(req)
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		return c.do(req, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := v.(*coalescedResult)
+	return result.toHTTPResponse(req, result.fromCache), nil
+}
+
+// do performs the actual lookup/round trip/store and returns a *coalescedResult rather than an
+// *http.Response, since the latter's Body is a single reader with one cursor that can't be shared
+// across the callers singleflight coalesces together.
+func (c *CachingClient) do(req *http.Request, key string) (*coalescedResult, error) {
+	now := time.Now()
+	cached, hasCached := c.Cache.Get(key)
+	if hasCached && cached.fresh(now) {
+		return &coalescedResult{CachedResponse: cached, fromCache: true}, nil
+	}
+
+	if hasCached {
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, readErr := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		// A 304 typically only repeats validator/rate-limit headers, not the full header set (e.g.
+		// Content-Type, pagination Link) the original 200 carried. Merge the fresh headers onto a
+		// clone of the cached ones instead of replacing them outright, so a later cache hit doesn't
+		// come back missing headers callers depend on.
+		header := cached.Header.Clone()
+		for k, values := range resp.Header {
+			header[k] = values
+		}
+
+		updated := &CachedResponse{
+			StatusCode: cached.StatusCode,
+			Header:     header,
+			Body:       cached.Body,
+			StoredAt:   now,
+			MaxAge:     maxAge(resp.Header),
+		}
+		c.Cache.Set(key, updated)
+		return &coalescedResult{CachedResponse: updated}, nil
+	}
+
+	result := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		StoredAt:   now,
+	}
+	if resp.StatusCode == http.StatusOK && isCacheable(resp) {
+		result.MaxAge = maxAge(resp.Header)
+		c.Cache.Set(key, result)
+	}
+	return &coalescedResult{CachedResponse: result}, nil
+}
+
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte(req.URL.String()))
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		h.Write([]byte(auth))
+	}
+	for _, header := range varyRequestHeaders {
+		h.Write([]byte(req.Header.Get(header)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func maxAge(h http.Header) time.Duration {
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "max-age=") {
+			continue
+		}
+		secs, err := strconv.Atoi(strings.TrimPrefix(part, "max-age="))
+		if err != nil || secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+func isCacheable(resp *http.Response) bool {
+	return maxAge(resp.Header) > 0 || resp.Header.Get("ETag") != ""
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCachingClientBypassesNonIdempotentMethods(t *testing.T) {
+	calls := 0
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		body, _ := ioutil.ReadAll(req.Body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+		}, nil
+	})}
+	cc := NewCachingClient(client, NewMemoryCache())
+
+	req1, _ := http.NewRequest(http.MethodPost, "https://example.com/graphql", strings.NewReader(`{"query":"a"}`))
+	req2, _ := http.NewRequest(http.MethodPost, "https://example.com/graphql", strings.NewReader(`{"query":"b"}`))
+
+	resp1, err := cc.Do(req1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body1, _ := ioutil.ReadAll(resp1.Body)
+
+	resp2, err := cc.Do(req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body2, _ := ioutil.ReadAll(resp2.Body)
+
+	if calls != 2 {
+		t.Fatalf("underlying client called %d times, want 2 (POSTs must never be coalesced or cached)", calls)
+	}
+	if string(body1) == string(body2) {
+		t.Fatalf("both POST callers got the same body %q; distinct request bodies must not be coalesced", body1)
+	}
+}
+
+func TestCachingClientRevalidationMergesHeaders(t *testing.T) {
+	first := true
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if first {
+			first = false
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header: http.Header{
+					"Content-Type":          {"application/json"},
+					"Etag":                  {`"v1"`},
+					"X-Ratelimit-Remaining": {"100"},
+				},
+				Body: ioutil.NopCloser(strings.NewReader(`{"ok":true}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header: http.Header{
+				"X-Ratelimit-Remaining": {"99"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	})}
+	cc := NewCachingClient(client, NewMemoryCache())
+
+	req1, _ := http.NewRequest(http.MethodGet, "https://example.com/repo", nil)
+	if _, err := cc.Do(req1); err != nil {
+		t.Fatal(err)
+	}
+
+	req2, _ := http.NewRequest(http.MethodGet, "https://example.com/repo", nil)
+	resp2, err := cc.Do(req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp2.Header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type after revalidation = %q, want it preserved from the original 200", got)
+	}
+	if got := resp2.Header.Get("X-Ratelimit-Remaining"); got != "99" {
+		t.Fatalf("X-Ratelimit-Remaining after revalidation = %q, want the fresh 304 value (99)", got)
+	}
+}
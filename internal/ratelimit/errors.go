@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitError is returned by a Limiter or Monitor-aware client when a request would exceed (or
+// has already exceeded) a rate limit. It carries enough detail for the caller to decide how long to
+// wait and which limit was hit, rather than having to parse an error string.
+type RateLimitError struct {
+	// Resource identifies which rate limit was exceeded, e.g. "github.primary", "github.search",
+	// "gitlab". Empty when the limiter doesn't distinguish resources.
+	Resource string
+	// RetryAfter is how long the caller should wait before retrying.
+	RetryAfter time.Duration
+	// Reset is when the limit is expected to replenish, if known.
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Resource != "" {
+		return fmt.Sprintf("rate limit exceeded for %s, retry after %s", e.Resource, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// Is reports whether target is ErrExceeded, so existing `errors.Is(err, ratelimit.ErrExceeded)`
+// checks continue to work against the richer *RateLimitError.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrExceeded
+}
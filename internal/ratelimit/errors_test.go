@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimitErrorIsErrExceeded(t *testing.T) {
+	err := &RateLimitError{Resource: "github.primary", RetryAfter: 30 * time.Second}
+
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatal("errors.Is(err, ErrExceeded) = false, want true: existing call sites depend on this")
+	}
+	if errors.Is(err, errors.New("rate limit exceeded")) {
+		t.Fatal("errors.Is matched an unrelated error with the same message; Is must compare by identity")
+	}
+}
+
+func TestRateLimitErrorString(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *RateLimitError
+		want string
+	}{
+		{
+			name: "with resource",
+			err:  &RateLimitError{Resource: "github.search", RetryAfter: 5 * time.Second},
+			want: "rate limit exceeded for github.search, retry after 5s",
+		},
+		{
+			name: "without resource",
+			err:  &RateLimitError{RetryAfter: 2 * time.Minute},
+			want: "rate limit exceeded, retry after 2m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Fatalf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import "time"
+
+// EventKind identifies the kind of occurrence a rate-limit Event describes.
+type EventKind int
+
+const (
+	// EventUpdated is published whenever Update records fresh rate-limit headers.
+	EventUpdated EventKind = iota
+	// EventThrottled is published the moment remaining crosses one of Monitor.Thresholds going down,
+	// or whenever RecommendedWaitForBackgroundOp recommends a non-zero wait.
+	EventThrottled
+	// EventRetryAfterSet is published whenever a Retry-After header is recorded.
+	EventRetryAfterSet
+	// EventExhausted is published the moment remaining reaches zero.
+	EventExhausted
+)
+
+// Event describes a single rate-limit occurrence published by a Monitor, for callers that want to
+// export metrics or surface progress on long-running background jobs (e.g. "waiting due to GitHub
+// rate limit: ~7m").
+type Event struct {
+	Kind      EventKind
+	Resource  string
+	Remaining int
+	Limit     int
+	Reset     time.Time
+}
+
+// defaultThresholds are the fractions of Limit at which Update publishes an EventThrottled when
+// Monitor.Thresholds is unset. Sorted descending: callers relying on effectiveThresholds()'s
+// crossing-count logic depend on that order.
+var defaultThresholds = []float64{0.25, 0.10}
+
+// eventBusBuffer is the per-subscriber channel buffer; once full, further events are dropped for
+// that subscriber rather than blocking the publisher.
+const eventBusBuffer = 16
+
+// effectiveThresholds returns c.Thresholds, or defaultThresholds if unset.
+func (c *Monitor) effectiveThresholds() []float64 {
+	if len(c.Thresholds) > 0 {
+		return c.Thresholds
+	}
+	return defaultThresholds
+}
+
+// breachLevel reports how many of c.effectiveThresholds() the given remaining/limit fraction falls
+// below, plus one more if remaining is fully exhausted. 0 means no threshold is breached.
+func (c *Monitor) breachLevel(remaining, limit int) int {
+	thresholds := c.effectiveThresholds()
+	if limit <= 0 {
+		return 0
+	}
+	if remaining <= 0 {
+		return len(thresholds) + 1
+	}
+
+	frac := float64(remaining) / float64(limit)
+	level := 0
+	for _, t := range thresholds {
+		if frac >= t {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+// Subscribe returns a channel of Events published by c, and an unsubscribe func the caller must call
+// once it's done reading (e.g. via defer, or when the background job it's tracking finishes) so the
+// channel stops accumulating events and can be garbage collected. The bus never blocks a publisher
+// on a slow consumer: once a subscriber's buffer is full, further events are dropped for it rather
+// than queued indefinitely, so it is safe to have many subscribers.
+func (c *Monitor) Subscribe() (events <-chan Event, unsubscribe func()) {
+	ch := make(chan Event, eventBusBuffer)
+
+	c.mu.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.mu.Unlock()
+
+	return ch, func() { c.unsubscribe(ch) }
+}
+
+func (c *Monitor) unsubscribe(ch chan Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sub := range c.subscribers {
+		if sub == ch {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// publishLocked fans e out to every subscriber. c.mu must be held.
+func (c *Monitor) publishLocked(e Event) {
+	if len(c.subscribers) == 0 {
+		return
+	}
+
+	e.Resource = c.Resource
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
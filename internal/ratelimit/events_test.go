@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMonitorBreachLevel(t *testing.T) {
+	m := &Monitor{}
+
+	tests := []struct {
+		name      string
+		remaining int
+		limit     int
+		want      int
+	}{
+		{"above both thresholds", 80, 100, 0},
+		{"just below 25%", 24, 100, 1},
+		{"just below 10%", 9, 100, 2},
+		{"fully exhausted", 0, 100, 3},
+		{"unknown limit", 5, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.breachLevel(tt.remaining, tt.limit); got != tt.want {
+				t.Fatalf("breachLevel(%d, %d) = %d, want %d", tt.remaining, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorUpdatePublishesOnlyOnCrossing(t *testing.T) {
+	m := &Monitor{}
+	events, unsubscribe := m.Subscribe()
+	defer unsubscribe()
+
+	header := func(limit, remaining int) http.Header {
+		return http.Header{
+			"X-Ratelimit-Limit":     {strconv.Itoa(limit)},
+			"X-Ratelimit-Remaining": {strconv.Itoa(remaining)},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+		}
+	}
+	m.HeaderPrefix = "X-"
+
+	// Crossing below 25% should publish EventUpdated + EventThrottled.
+	m.Update(header(100, 24))
+	drainAndExpect(t, events, EventUpdated, EventThrottled)
+
+	// Staying below 25% (but above 10%) on the next update must not re-publish EventThrottled.
+	m.Update(header(100, 20))
+	drainAndExpect(t, events, EventUpdated)
+
+	// Crossing below 10% publishes another EventThrottled.
+	m.Update(header(100, 5))
+	drainAndExpect(t, events, EventUpdated, EventThrottled)
+
+	// Hitting zero publishes EventExhausted.
+	m.Update(header(100, 0))
+	drainAndExpect(t, events, EventUpdated, EventExhausted)
+
+	// Recovering back above every threshold resets lastBreachLevel without publishing a breach event.
+	m.Update(header(100, 90))
+	drainAndExpect(t, events, EventUpdated)
+}
+
+func TestMonitorUnsubscribeStopsDeliveryAndShrinksSubscribers(t *testing.T) {
+	m := &Monitor{HeaderPrefix: "X-"}
+	events, unsubscribe := m.Subscribe()
+
+	header := func(limit, remaining int) http.Header {
+		return http.Header{
+			"X-Ratelimit-Limit":     {strconv.Itoa(limit)},
+			"X-Ratelimit-Remaining": {strconv.Itoa(remaining)},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+		}
+	}
+
+	m.Update(header(100, 80))
+	drainAndExpect(t, events, EventUpdated)
+
+	if n := len(m.subscribers); n != 1 {
+		t.Fatalf("len(subscribers) after Subscribe = %d, want 1", n)
+	}
+
+	unsubscribe()
+
+	if n := len(m.subscribers); n != 0 {
+		t.Fatalf("len(subscribers) after unsubscribe = %d, want 0: the channel must be removed", n)
+	}
+
+	m.Update(header(100, 70))
+	select {
+	case e := <-events:
+		t.Fatalf("received %+v after unsubscribe; publishLocked must not deliver to a removed channel", e)
+	default:
+	}
+}
+
+func TestMonitorUnsubscribeOnlyRemovesItsOwnChannel(t *testing.T) {
+	m := &Monitor{HeaderPrefix: "X-"}
+	eventsA, unsubA := m.Subscribe()
+	eventsB, unsubB := m.Subscribe()
+	defer unsubB()
+
+	unsubA()
+
+	if n := len(m.subscribers); n != 1 {
+		t.Fatalf("len(subscribers) after unsubscribing one of two = %d, want 1", n)
+	}
+
+	m.Update(http.Header{
+		"X-Ratelimit-Limit":     {"100"},
+		"X-Ratelimit-Remaining": {"80"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+	})
+
+	select {
+	case <-eventsA:
+		t.Fatal("unsubscribed channel A still received an event")
+	default:
+	}
+	select {
+	case e := <-eventsB:
+		if e.Kind != EventUpdated {
+			t.Fatalf("channel B event kind = %v, want EventUpdated", e.Kind)
+		}
+	default:
+		t.Fatal("still-subscribed channel B should have received the event")
+	}
+}
+
+func drainAndExpect(t *testing.T, events <-chan Event, want ...EventKind) {
+	t.Helper()
+	for i, kind := range want {
+		select {
+		case e := <-events:
+			if e.Kind != kind {
+				t.Fatalf("event %d kind = %v, want %v", i, e.Kind, kind)
+			}
+		default:
+			t.Fatalf("expected %d events, got %d", len(want), i)
+		}
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected extra event: %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GitHub rate-limit resource names, as reported in the X-RateLimit-Resource response header. See
+// https://docs.github.com/en/rest/rate-limit/rate-limit#about-rate-limits.
+const (
+	ResourceGitHubCore                = "core"
+	ResourceGitHubSearch              = "search"
+	ResourceGitHubGraphQL             = "graphql"
+	ResourceGitHubIntegrationManifest = "integration_manifest"
+	ResourceGitHubCodeScanningUpload  = "code_scanning_upload"
+
+	// ResourceGitLab is the bucket used for GitLab.com's global 600 req/h limit, which (unlike
+	// GitHub) isn't split into named resources.
+	ResourceGitLab = "gitlab"
+)
+
+// MultiMonitor tracks a code host's independent rate-limit categories as separate Monitor buckets,
+// rather than assuming a single global limit. GitHub reports a distinct X-RateLimit-Resource (core
+// REST, search, GraphQL, integration manifest, code scanning upload) per response, each replenishing
+// on its own schedule; GitLab.com instead enforces one global 600 req/h limit. MultiMonitor
+// dispatches each Update to the right bucket so RecommendedWaitForBackgroundOp can give
+// resource-specific advice instead of a single one-size-fits-all wait.
+type MultiMonitor struct {
+	HeaderPrefix string // "X-" (GitHub) or "" (GitLab)
+
+	mu      sync.Mutex
+	buckets map[string]*Monitor
+
+	clock func() time.Time
+}
+
+// NewMultiMonitor creates a MultiMonitor for a code host whose rate-limit headers are prefixed by
+// headerPrefix ("X-" for GitHub, "" for GitLab).
+func NewMultiMonitor(headerPrefix string) *MultiMonitor {
+	return &MultiMonitor{
+		HeaderPrefix: headerPrefix,
+		buckets:      make(map[string]*Monitor),
+	}
+}
+
+// Update dispatches h to the bucket named by the X-RateLimit-Resource header, or ResourceGitLab for
+// GitLab responses, which carry no such header but do carry RateLimit-Remaining. Responses with
+// neither are ignored.
+func (m *MultiMonitor) Update(h http.Header) {
+	resource := h.Get(m.HeaderPrefix + "RateLimit-Resource")
+	if resource == "" {
+		if h.Get(m.HeaderPrefix+"RateLimit-Remaining") == "" {
+			return
+		}
+		resource = ResourceGitLab
+	}
+
+	m.bucket(resource).Update(h)
+}
+
+// ReportGraphQLCost records the remaining points, point limit and reset time of the graphql bucket,
+// as parsed from a GitHub GraphQL response's rateLimit { limit remaining resetAt } field. GitHub's
+// GraphQL API only reports remaining points in the response body, not in headers, so callers must
+// report it explicitly rather than relying on Update. It takes no per-query cost: the bucket's state
+// is the reported remaining count, not a running total of costs.
+func (m *MultiMonitor) ReportGraphQLCost(remaining, limit int, resetAt time.Time) {
+	b := m.bucket(ResourceGitHubGraphQL)
+
+	b.mu.Lock()
+	b.applyLocked(limit, remaining, resetAt)
+	b.mu.Unlock()
+}
+
+// RecommendedWaitForBackgroundOp returns the recommended wait before performing a cost-cost
+// background operation against the given resource bucket. See
+// Monitor.RecommendedWaitForBackgroundOp.
+func (m *MultiMonitor) RecommendedWaitForBackgroundOp(resource string, cost int) time.Duration {
+	return m.bucket(resource).RecommendedWaitForBackgroundOp(cost)
+}
+
+// Get reports the given resource bucket's rate limit status, as of its last update.
+func (m *MultiMonitor) Get(resource string) (remaining int, reset, retry time.Duration, known bool) {
+	return m.bucket(resource).Get()
+}
+
+func (m *MultiMonitor) bucket(resource string) *Monitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[resource]
+	if !ok {
+		b = &Monitor{HeaderPrefix: m.HeaderPrefix, Resource: resource, clock: m.clock}
+		m.buckets[resource] = b
+	}
+	return b
+}
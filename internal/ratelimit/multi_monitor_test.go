@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMultiMonitorUpdateDispatchesByResource(t *testing.T) {
+	m := NewMultiMonitor("X-")
+
+	m.Update(http.Header{
+		"X-Ratelimit-Resource":  {ResourceGitHubSearch},
+		"X-Ratelimit-Limit":     {"30"},
+		"X-Ratelimit-Remaining": {"5"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)},
+	})
+
+	remaining, _, _, known := m.Get(ResourceGitHubSearch)
+	if !known || remaining != 5 {
+		t.Fatalf("Get(search) = (remaining=%d, known=%v), want (5, true)", remaining, known)
+	}
+
+	// A resource that never had an Update dispatched to it must not pick up search's state.
+	remaining, _, _, known = m.Get(ResourceGitHubCore)
+	if known || remaining != 0 {
+		t.Fatalf("Get(core) = (remaining=%d, known=%v), want the zero value (never updated)", remaining, known)
+	}
+}
+
+func TestMultiMonitorUpdateGitLabFallback(t *testing.T) {
+	m := NewMultiMonitor("")
+
+	m.Update(http.Header{
+		"Ratelimit-Limit":     {"600"},
+		"Ratelimit-Remaining": {"450"},
+		"Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+	})
+
+	remaining, _, _, known := m.Get(ResourceGitLab)
+	if !known || remaining != 450 {
+		t.Fatalf("Get(gitlab) = (remaining=%d, known=%v), want (450, true)", remaining, known)
+	}
+}
+
+func TestMultiMonitorUpdateIgnoresHeadersWithNeitherSignal(t *testing.T) {
+	m := NewMultiMonitor("X-")
+
+	m.Update(http.Header{"Content-Type": {"application/json"}})
+
+	if len(m.buckets) != 0 {
+		t.Fatalf("len(buckets) = %d, want 0 (nothing should be dispatched)", len(m.buckets))
+	}
+}
+
+func TestMultiMonitorBucketsAreIsolated(t *testing.T) {
+	m := NewMultiMonitor("X-")
+
+	m.Update(http.Header{
+		"X-Ratelimit-Resource":  {ResourceGitHubCore},
+		"X-Ratelimit-Limit":     {"5000"},
+		"X-Ratelimit-Remaining": {"4000"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+	})
+	m.Update(http.Header{
+		"X-Ratelimit-Resource":  {ResourceGitHubSearch},
+		"X-Ratelimit-Limit":     {"30"},
+		"X-Ratelimit-Remaining": {"1"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)},
+	})
+
+	coreRemaining, _, _, _ := m.Get(ResourceGitHubCore)
+	searchRemaining, _, _, _ := m.Get(ResourceGitHubSearch)
+	if coreRemaining != 4000 {
+		t.Fatalf("core remaining = %d, want 4000 (must not be clobbered by the search bucket's Update)", coreRemaining)
+	}
+	if searchRemaining != 1 {
+		t.Fatalf("search remaining = %d, want 1", searchRemaining)
+	}
+}
+
+func TestMultiMonitorReportGraphQLCostPublishesThroughApplyLocked(t *testing.T) {
+	m := NewMultiMonitor("X-")
+
+	// Subscribe before the first report so the bucket exists and we can observe what it publishes.
+	events, unsubscribe := m.bucket(ResourceGitHubGraphQL).Subscribe()
+	defer unsubscribe()
+
+	resetAt := time.Now().Add(time.Hour)
+	m.ReportGraphQLCost(10, 5000, resetAt)
+
+	select {
+	case e := <-events:
+		if e.Kind != EventUpdated || e.Remaining != 10 || e.Limit != 5000 {
+			t.Fatalf("event = %+v, want Kind=EventUpdated Remaining=10 Limit=5000", e)
+		}
+	default:
+		t.Fatal("ReportGraphQLCost did not publish an event; it must route through applyLocked")
+	}
+
+	remaining, _, _, known := m.Get(ResourceGitHubGraphQL)
+	if !known || remaining != 10 {
+		t.Fatalf("Get(graphql) = (remaining=%d, known=%v), want (10, true)", remaining, known)
+	}
+}
+
+func TestMultiMonitorReportGraphQLCostPublishesThrottledOnCrossing(t *testing.T) {
+	m := NewMultiMonitor("X-")
+
+	events, unsubscribe := m.bucket(ResourceGitHubGraphQL).Subscribe()
+	defer unsubscribe()
+
+	// 4% of 5000 remaining crosses below both default thresholds (25%, 10%) in one report, so
+	// applyLocked should publish EventThrottled right after EventUpdated.
+	m.ReportGraphQLCost(200, 5000, time.Now().Add(time.Hour))
+
+	first := <-events
+	if first.Kind != EventUpdated {
+		t.Fatalf("first event kind = %v, want EventUpdated", first.Kind)
+	}
+	select {
+	case second := <-events:
+		if second.Kind != EventThrottled {
+			t.Fatalf("second event kind = %v, want EventThrottled", second.Kind)
+		}
+	default:
+		t.Fatal("expected a second EventThrottled event; ReportGraphQLCost must route through applyLocked's crossing logic")
+	}
+}
@@ -44,16 +44,20 @@ type NonBlockingLimiter struct {
 	r *rate.Limiter
 }
 
-// Limit checks if the rate limit has been exceeded and returns ErrExceeded otherwise nil
+// Limit checks if the rate limit has been exceeded and returns a *RateLimitError otherwise nil
 func (bl *NonBlockingLimiter) Limit(ctx context.Context, n int) error {
-	res := bl.r.ReserveN(time.Now(), n)
+	now := time.Now()
+	res := bl.r.ReserveN(now, n)
 	if res.OK() {
 		return nil
 	}
+	retryAfter := res.DelayFrom(now)
 	res.Cancel()
-	return ErrExceeded
+	return &RateLimitError{RetryAfter: retryAfter}
 }
 
+// ErrExceeded is kept for existing `errors.Is(err, ratelimit.ErrExceeded)` call sites. Limiters now
+// return the richer *RateLimitError, whose Is method reports true against this sentinel.
 var ErrExceeded = errors.New("rate limit exceeded")
 
 // Monitor monitors an external service's rate limit based on the X-RateLimit-Remaining or RateLimit-Remaining
@@ -63,12 +67,24 @@ var ErrExceeded = errors.New("rate limit exceeded")
 type Monitor struct {
 	HeaderPrefix string // "X-" (GitHub) or "" (GitLab)
 
-	mu        sync.Mutex
-	known     bool
-	limit     int       // last RateLimit-Limit HTTP response header value
-	remaining int       // last RateLimit-Remaining HTTP response header value
-	reset     time.Time // last RateLimit-Remaining HTTP response header value
-	retry     time.Time // deadline based on Retry-After HTTP response header value
+	// Resource optionally names which rate-limit bucket this Monitor tracks (e.g. "search",
+	// "graphql", "gitlab"). Set by MultiMonitor on the buckets it creates; empty for a standalone
+	// Monitor tracking a single global limit.
+	Resource string
+
+	// Thresholds are the fractions of Limit (sorted descending) at which Update publishes an
+	// EventThrottled as remaining crosses below them. Defaults to {0.25, 0.10} if unset.
+	Thresholds []float64
+
+	mu              sync.Mutex
+	known           bool
+	limit           int       // last RateLimit-Limit HTTP response header value
+	remaining       int       // last RateLimit-Remaining HTTP response header value
+	reset           time.Time // last RateLimit-Reset HTTP response header value
+	retry           time.Time // deadline based on Retry-After HTTP response header value
+	lastBreachLevel int       // breachLevel as of the last publish, so Update only fires on a crossing
+
+	subscribers []chan Event
 
 	clock func() time.Time
 }
@@ -81,12 +97,6 @@ func (c *Monitor) Get() (remaining int, reset, retry time.Duration, known bool)
 	return c.remaining, c.reset.Sub(now), c.retry.Sub(now), c.known
 }
 
-// TODO(keegancsmith) Update RecommendedWaitForBackgroundOp to work with other
-// rate limits. Such as:
-//
-// - GitHub search 30req/m
-// - GitLab.com 600 req/h
-
 // RecommendedWaitForBackgroundOp returns the recommended wait time before performing a periodic
 // background operation with the given rate limit cost. It takes the rate limit information from the last API
 // request into account.
@@ -106,9 +116,14 @@ func (c *Monitor) Get() (remaining int, reset, retry time.Duration, known bool)
 // out-of-synchronization.
 //
 // See https://developer.github.com/v4/guides/resource-limitations/#rate-limit.
-func (c *Monitor) RecommendedWaitForBackgroundOp(cost int) time.Duration {
+func (c *Monitor) RecommendedWaitForBackgroundOp(cost int) (wait time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	defer func() {
+		if wait > 0 {
+			c.publishLocked(Event{Kind: EventThrottled, Remaining: c.remaining, Limit: c.limit, Reset: c.reset})
+		}
+	}()
 
 	now := c.now()
 	if !c.retry.IsZero() {
@@ -164,6 +179,7 @@ func (c *Monitor) Update(h http.Header) {
 	retry, _ := strconv.ParseInt(h.Get("Retry-After"), 10, 64)
 	if retry > 0 {
 		c.retry = c.now().Add(time.Duration(retry) * time.Second)
+		c.publishLocked(Event{Kind: EventRetryAfterSet, Reset: c.retry})
 	}
 
 	// See https://developer.github.com/v3/#rate-limiting.
@@ -182,10 +198,33 @@ func (c *Monitor) Update(h http.Header) {
 		c.known = false
 		return
 	}
+	c.applyLocked(limit, remaining, time.Unix(resetAtSeconds, 0))
+}
+
+// applyLocked records a fresh known rate-limit state and publishes EventUpdated plus, on an actual
+// threshold crossing, EventThrottled or EventExhausted. c.mu must be held.
+//
+// Publishing is crossing-triggered rather than level-triggered: a client sitting well below a
+// threshold across many Updates only gets one EventThrottled for it, not one per call, so a busy
+// subscriber's bounded channel isn't flooded with duplicates that crowd out the one-shot
+// EventExhausted/EventRetryAfterSet events downstream consumers actually need.
+func (c *Monitor) applyLocked(limit, remaining int, reset time.Time) {
 	c.known = true
 	c.limit = limit
 	c.remaining = remaining
-	c.reset = time.Unix(resetAtSeconds, 0)
+	c.reset = reset
+
+	c.publishLocked(Event{Kind: EventUpdated, Remaining: remaining, Limit: limit, Reset: reset})
+
+	level := c.breachLevel(remaining, limit)
+	if level > c.lastBreachLevel {
+		kind := EventThrottled
+		if level > len(c.effectiveThresholds()) {
+			kind = EventExhausted
+		}
+		c.publishLocked(Event{Kind: kind, Remaining: remaining, Limit: limit, Reset: reset})
+	}
+	c.lastBreachLevel = level
 }
 
 func (c *Monitor) now() time.Time {
@@ -0,0 +1,275 @@
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RateLimitEventKind identifies the kind of rate-limit occurrence a Transport observed.
+type RateLimitEventKind int
+
+const (
+	// EventGitHubPrimary is GitHub's primary, header-driven rate limit (X-RateLimit-Remaining: 0).
+	EventGitHubPrimary RateLimitEventKind = iota
+	// EventGitHubSecondary is GitHub's undocumented "secondary rate limit" / abuse detection, signaled
+	// by a 403/429 with a Retry-After and a matching message in the response body.
+	EventGitHubSecondary
+	// EventGitLabReset is GitLab's RateLimit-Reset-driven limit.
+	EventGitLabReset
+)
+
+// RateLimitEvent describes a single rate-limit related retry performed by a Transport, for callers
+// that want to log or surface backoff behavior (e.g. repo-updater, code intel uploaders).
+type RateLimitEvent struct {
+	Kind       RateLimitEventKind
+	RetryAfter time.Duration
+	Attempt    int
+}
+
+// defaultMaxRetries is how many times Transport retries a rate-limited request before giving up and
+// returning a *RateLimitError to the caller.
+const defaultMaxRetries = 5
+
+// errCannotRetryRequestBody is returned when a retry is needed but req.Body can't be re-read because
+// the caller didn't set req.GetBody (http.NewRequestWithContext sets it automatically for common
+// body types).
+var errCannotRetryRequestBody = errors.New("ratelimit: request body is not rewindable for retry")
+
+// githubSecondaryRateLimitMarkers are substrings of GitHub's documented secondary rate limit and
+// (legacy) abuse detection error messages. See
+// https://docs.github.com/en/rest/guides/best-practices-for-using-the-rest-api#secondary-rate-limits.
+var githubSecondaryRateLimitMarkers = []string{
+	"secondary rate limit",
+	"abuse detection mechanism",
+}
+
+// Transport is an http.RoundTripper that pairs an embedded Monitor with retry/backoff handling for
+// the rate-limit conventions used by GitHub and GitLab. Before each request it waits according to
+// Limiter (if set) or Monitor.RecommendedWaitForBackgroundOp; after each response it feeds the
+// response headers back into Monitor.Update and, for retryable rate-limit responses, sleeps and
+// retries with jittered exponential backoff. It distinguishes GitHub's primary rate limit
+// (X-RateLimit-Remaining: 0), GitHub's secondary rate limit (body match + Retry-After), and GitLab's
+// RateLimit-Reset, emitting a RateLimitEvent for each retry on Events so callers can log or surface
+// them.
+//
+// Today Monitor.Update is purely passive bookkeeping; Transport turns it into a drop-in client layer
+// so every HTTP client in the repo gets consistent rate-limit behavior.
+type Transport struct {
+	// Base is the underlying RoundTripper. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+
+	// Monitor is updated from every response and consulted for the wait before each request when
+	// Limiter is unset.
+	Monitor *Monitor
+
+	// Limiter, if set, is consulted before every request instead of Monitor.RecommendedWaitForBackgroundOp.
+	Limiter Limiter
+
+	// MaxRetries is the maximum number of retries for a rate-limited response. Defaults to
+	// defaultMaxRetries.
+	MaxRetries int
+
+	// Events, if set, receives a RateLimitEvent whenever a retry is scheduled. Sends are
+	// non-blocking: a slow consumer misses events rather than stalling the request.
+	Events chan RateLimitEvent
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	monitor := t.Monitor
+	if monitor == nil {
+		// A caller that sets Limiter instead of Monitor (the type's doc comment offers both) still
+		// needs somewhere to route Update so the retry/classify logic below has a Monitor to read
+		// from; an unconfigured one is a no-op rather than a nil-pointer panic.
+		monitor = &Monitor{}
+	}
+
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req.GetBody == nil && req.Body != nil {
+				return nil, errCannotRetryRequestBody
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		}
+
+		if err := t.wait(req.Context(), monitor); err != nil {
+			return nil, err
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		monitor.Update(resp.Header)
+
+		wait, kind, retryable := classifyRateLimitResponse(resp)
+		if !retryable {
+			return resp, nil
+		}
+		if attempt >= maxRetries {
+			resp.Body.Close()
+			return nil, &RateLimitError{Resource: resourceForEventKind(kind), RetryAfter: wait, Reset: time.Now().Add(wait)}
+		}
+
+		resp.Body.Close()
+		t.emit(RateLimitEvent{Kind: kind, RetryAfter: wait, Attempt: attempt + 1})
+
+		if err := sleepContext(req.Context(), wait+jitter(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Transport) wait(ctx context.Context, monitor *Monitor) error {
+	if t.Limiter != nil {
+		return t.Limiter.Limit(ctx, 1)
+	}
+
+	if wait := monitor.RecommendedWaitForBackgroundOp(1); wait > 0 {
+		return sleepContext(ctx, wait)
+	}
+
+	return nil
+}
+
+func (t *Transport) emit(e RateLimitEvent) {
+	if t.Events == nil {
+		return
+	}
+	select {
+	case t.Events <- e:
+	default:
+	}
+}
+
+// classifyRateLimitResponse reports whether resp represents a retryable rate-limit response and, if
+// so, how long to wait before retrying and which kind of limit was hit.
+func classifyRateLimitResponse(resp *http.Response) (wait time.Duration, kind RateLimitEventKind, retryable bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, 0, false
+	}
+
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		wait := retryAfter
+		if wait == 0 {
+			wait = time.Until(time.Unix(parseInt64(resp.Header.Get("X-RateLimit-Reset")), 0))
+		}
+		return wait, EventGitHubPrimary, true
+	}
+
+	if retryAfter > 0 && hasGitHubSecondaryRateLimitMessage(resp) {
+		return retryAfter, EventGitHubSecondary, true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if resetAt := resp.Header.Get("RateLimit-Reset"); resetAt != "" {
+			if wait := time.Until(time.Unix(parseInt64(resetAt), 0)); wait > 0 {
+				return wait, EventGitLabReset, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// hasGitHubSecondaryRateLimitMessage reports whether resp's body matches one of GitHub's documented
+// secondary rate limit / abuse detection messages, consuming and restoring resp.Body so callers can
+// still read it afterwards.
+func hasGitHubSecondaryRateLimitMessage(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+	if err != nil {
+		return false
+	}
+
+	lower := strings.ToLower(string(body))
+	for _, marker := range githubSecondaryRateLimitMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRetryAfter(v string) time.Duration {
+	secs, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func parseInt64(v string) int64 {
+	n, _ := strconv.ParseInt(v, 10, 64)
+	return n
+}
+
+// resourceForEventKind names the rate-limit bucket a RateLimitEventKind corresponds to, for
+// populating RateLimitError.Resource once Transport gives up retrying.
+func resourceForEventKind(kind RateLimitEventKind) string {
+	switch kind {
+	case EventGitHubPrimary:
+		return "github.primary"
+	case EventGitHubSecondary:
+		return "github.secondary"
+	case EventGitLabReset:
+		return "gitlab"
+	default:
+		return ""
+	}
+}
+
+// jitter returns a random jittered backoff for the given retry attempt (0-indexed), capped at 30s.
+func jitter(attempt int) time.Duration {
+	backoff := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+	if backoff > 30*time.Second {
+		backoff = 30 * time.Second
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestClassifyRateLimitResponse(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		body          string
+		wantRetryable bool
+		wantKind      RateLimitEventKind
+		wantWait      time.Duration
+	}{
+		{
+			name:   "github primary via Retry-After",
+			status: http.StatusForbidden,
+			header: http.Header{
+				"X-Ratelimit-Remaining": {"0"},
+				"Retry-After":           {"30"},
+			},
+			wantRetryable: true,
+			wantKind:      EventGitHubPrimary,
+			wantWait:      30 * time.Second,
+		},
+		{
+			name:   "github secondary rate limit",
+			status: http.StatusForbidden,
+			header: http.Header{
+				"Retry-After": {"5"},
+			},
+			body:          "You have exceeded a secondary rate limit. Please retry later.",
+			wantRetryable: true,
+			wantKind:      EventGitHubSecondary,
+			wantWait:      5 * time.Second,
+		},
+		{
+			name:   "github legacy abuse detection message",
+			status: http.StatusForbidden,
+			header: http.Header{
+				"Retry-After": {"2"},
+			},
+			body:          "You have triggered an abuse detection mechanism.",
+			wantRetryable: true,
+			wantKind:      EventGitHubSecondary,
+			wantWait:      2 * time.Second,
+		},
+		{
+			name:   "gitlab RateLimit-Reset",
+			status: http.StatusTooManyRequests,
+			header: http.Header{
+				"Ratelimit-Reset": {strconv.FormatInt(now.Add(1*time.Minute).Unix(), 10)},
+			},
+			wantRetryable: true,
+			wantKind:      EventGitLabReset,
+		},
+		{
+			name:   "403 with no rate-limit signal is not retryable",
+			status: http.StatusForbidden,
+			header: http.Header{},
+			body:   "you do not have access to this repository",
+		},
+		{
+			name:   "ordinary success is not retryable",
+			status: http.StatusOK,
+			header: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     tt.header,
+				Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			wait, kind, retryable := classifyRateLimitResponse(resp)
+			if retryable != tt.wantRetryable {
+				t.Fatalf("retryable = %v, want %v", retryable, tt.wantRetryable)
+			}
+			if !tt.wantRetryable {
+				return
+			}
+			if kind != tt.wantKind {
+				t.Fatalf("kind = %v, want %v", kind, tt.wantKind)
+			}
+			if tt.wantWait != 0 && wait != tt.wantWait {
+				t.Fatalf("wait = %v, want %v", wait, tt.wantWait)
+			}
+			if wait <= 0 {
+				t.Fatalf("wait = %v, want a positive duration", wait)
+			}
+		})
+	}
+}
+
+func TestTransportWithLimiterButNoMonitorDoesNotPanic(t *testing.T) {
+	// Transport's doc comment offers Limiter as an alternative to Monitor for the pre-request wait,
+	// so a caller that sets Limiter and leaves Monitor unset must not panic on the Update/Wait calls
+	// that follow every response.
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	tr := &Transport{
+		Base:    base,
+		Limiter: NewNonBlockingLimiter(rate.NewLimiter(rate.Inf, 1)),
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/repo", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip returned an unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestHasGitHubSecondaryRateLimitMessagePreservesBody(t *testing.T) {
+	const body = "You have exceeded a secondary rate limit."
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader(body))}
+
+	if !hasGitHubSecondaryRateLimitMessage(resp) {
+		t.Fatal("expected a secondary rate limit match")
+	}
+
+	got, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Fatalf("body after classification = %q, want %q (must be restored for later reads)", got, body)
+	}
+}